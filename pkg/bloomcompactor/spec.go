@@ -0,0 +1,662 @@
+package bloomcompactor
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	v1 "github.com/grafana/loki/pkg/storage/bloom/v1"
+	"github.com/grafana/loki/pkg/storage/stores/shipper/bloomshipper"
+)
+
+// VerificationPolicy controls how SimpleBloomGenerator reacts to unhealthy
+// source blocks discovered during the pre-compaction verification pass.
+type VerificationPolicy int
+
+const (
+	// PolicyStrict aborts the compaction job as soon as a single unhealthy
+	// source block is found.
+	PolicyStrict VerificationPolicy = iota
+	// PolicyLenient skips unhealthy source blocks, recording them in the
+	// returned BlockHealthStats, and continues compacting the rest.
+	PolicyLenient
+)
+
+// BlockHealthStats reports the outcome of verifying a single source block
+// before it's merged by SimpleBloomGenerator.
+type BlockHealthStats struct {
+	SeriesCount          int
+	MalformedSeriesCount int
+	OutOfRangeFPCount    int
+	BitLengthMismatches  int
+}
+
+// Healthy reports whether the verified block had no defects.
+func (s BlockHealthStats) Healthy() bool {
+	return s.MalformedSeriesCount == 0 && s.OutOfRangeFPCount == 0 && s.BitLengthMismatches == 0
+}
+
+// verifyBlock walks every series in bq via Next()/At(), checking that:
+//   - series fingerprints are strictly monotonic
+//   - each series' chunks are sorted and non-overlapping
+//   - the bloom's bit-length matches the schema declared in the block's metadata
+//   - the declared fromFP/throughFP range in the block metadata bounds every series inside
+//
+// It checks ctx.Done() between series so a caller can cancel a long pre-check
+// on a huge tenant. bq is rewound to its start before returning so it can be
+// re-read during the merge phase.
+func verifyBlock(ctx context.Context, bq *v1.BlockQuerier) (BlockHealthStats, error) {
+	var stats BlockHealthStats
+
+	md, err := bq.Metadata()
+	if err != nil {
+		return stats, errors.Wrap(err, "failed to read block metadata")
+	}
+	defer bq.Reset()
+
+	var (
+		prevFP model.Fingerprint
+		haveFP bool
+		nBits  = md.Options.Schema.NBits()
+	)
+
+	for bq.Next() {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		swb := bq.At()
+		stats.SeriesCount++
+
+		fp := swb.Series.Fingerprint
+		if haveFP && fp <= prevFP {
+			stats.MalformedSeriesCount++
+		}
+		prevFP, haveFP = fp, true
+
+		if fp < md.FromFP || fp > md.ThroughFP {
+			stats.OutOfRangeFPCount++
+		}
+
+		if !chunksSortedAndNonOverlapping(swb.Series.Chunks) {
+			stats.MalformedSeriesCount++
+		}
+
+		if swb.Bloom.BitLen() != nBits {
+			stats.BitLengthMismatches++
+		}
+	}
+
+	if err := bq.Err(); err != nil {
+		return stats, errors.Wrap(err, "error iterating block series")
+	}
+
+	return stats, nil
+}
+
+// chunksSortedAndNonOverlapping reports whether chunks are ordered by `from`
+// and no chunk's range overlaps the next one's.
+func chunksSortedAndNonOverlapping(chunks v1.ChunkRefs) bool {
+	for i := 1; i < len(chunks); i++ {
+		if chunks[i].From < chunks[i-1].From || chunks[i].From < chunks[i-1].Through {
+			return false
+		}
+	}
+	return true
+}
+
+// verifySourceBlocks runs verifyBlock over every block, honoring policy:
+// PolicyStrict returns on the first unhealthy block, PolicyLenient drops
+// unhealthy blocks from the returned slice and records every block's stats
+// regardless of health. It's called from prepareSourceBlocks, which both
+// Generate and GenerateWithContext run before any merging begins, so a
+// corrupt block never reaches the merge phase.
+//
+// The original request also asked for this check to run in the
+// compactor's job-selection stage, before a SimpleBloomGenerator is even
+// constructed, so a corrupt block is excluded from a job rather than
+// merely dropped once one is running. That's explicitly descoped from
+// this series: this package has no job-selection entrypoint yet for it
+// to wire into.
+func verifySourceBlocks(
+	ctx context.Context,
+	blocks []*bloomshipper.CloseableBlockQuerier,
+	policy VerificationPolicy,
+) (healthy []*bloomshipper.CloseableBlockQuerier, stats map[*bloomshipper.CloseableBlockQuerier]BlockHealthStats, err error) {
+	stats = make(map[*bloomshipper.CloseableBlockQuerier]BlockHealthStats, len(blocks))
+	healthy = make([]*bloomshipper.CloseableBlockQuerier, 0, len(blocks))
+
+	for _, block := range blocks {
+		s, err := verifyBlock(ctx, block.BlockQuerier)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to verify source block")
+		}
+		stats[block] = s
+
+		if !s.Healthy() {
+			if policy == PolicyStrict {
+				return nil, stats, errors.Errorf("unhealthy source block: %+v", s)
+			}
+			continue
+		}
+
+		healthy = append(healthy, block)
+	}
+
+	return healthy, stats, nil
+}
+
+// Metrics holds the instrumentation used while building blooms. It currently
+// carries only the v1.Metrics passed to the bloom tokenizer.
+type Metrics struct {
+	bloomMetrics *v1.Metrics
+}
+
+// NewMetrics returns a new Metrics wrapping bloomMetrics. It registers no
+// metrics of its own; the registerer is unused.
+func NewMetrics(_ prometheus.Registerer, bloomMetrics *v1.Metrics) *Metrics {
+	return &Metrics{
+		bloomMetrics: bloomMetrics,
+	}
+}
+
+// ChunkItersByFingerprint pairs a series' fingerprint with an iterator over
+// its constituent chunks, as produced by a ChunkLoader.
+type ChunkItersByFingerprint struct {
+	fp  model.Fingerprint
+	itr v1.Iterator[v1.ChunkRefWithIter]
+}
+
+// ChunkLoader loads the chunks backing a single series so they can be
+// tokenized into a bloom.
+type ChunkLoader interface {
+	Load(ctx context.Context, userID string, series *v1.Series) (*ChunkItersByFingerprint, error)
+}
+
+// SimpleBloomGenerator builds a new set of bloom blocks for a tenant by
+// merging series from the tenant's chunk store with any already-compatible
+// source blocks.
+type SimpleBloomGenerator struct {
+	userID       string
+	opts         v1.BlockOptions
+	store        v1.Iterator[*v1.Series]
+	chunkLoader  ChunkLoader
+	blocks       []*bloomshipper.CloseableBlockQuerier
+	readWriterFn func() (v1.BlockWriter, v1.BlockReader)
+
+	metrics *Metrics
+	logger  log.Logger
+
+	// policy governs the pre-compaction verification pass run by Generate.
+	// Defaults to PolicyLenient; override with WithVerificationPolicy.
+	policy VerificationPolicy
+}
+
+// NewSimpleBloomGenerator constructs a SimpleBloomGenerator.
+func NewSimpleBloomGenerator(
+	userID string,
+	opts v1.BlockOptions,
+	store v1.Iterator[*v1.Series],
+	chunkLoader ChunkLoader,
+	blocks []*bloomshipper.CloseableBlockQuerier,
+	readWriterFn func() (v1.BlockWriter, v1.BlockReader),
+	metrics *Metrics,
+	logger log.Logger,
+) *SimpleBloomGenerator {
+	return &SimpleBloomGenerator{
+		userID:       userID,
+		opts:         opts,
+		store:        store,
+		chunkLoader:  chunkLoader,
+		blocks:       blocks,
+		readWriterFn: readWriterFn,
+		metrics:      metrics,
+		logger:       log.With(logger, "component", "bloom_generator"),
+		policy:       PolicyLenient,
+	}
+}
+
+// WithVerificationPolicy overrides the default (lenient) policy applied to
+// source blocks during Generate's pre-compaction verification pass.
+func (s *SimpleBloomGenerator) WithVerificationPolicy(policy VerificationPolicy) *SimpleBloomGenerator {
+	s.policy = policy
+	return s
+}
+
+// prepareSourceBlocks runs the pre-compaction verification pass and filters
+// out any remaining blocks with an incompatible schema, returning the
+// blocks ready to merge and every block excluded, for either reason.
+func (s *SimpleBloomGenerator) prepareSourceBlocks(ctx context.Context, stats *Stats) (ready, skipped []*bloomshipper.CloseableBlockQuerier, err error) {
+	stats.AddSourceBlocksConsidered(int64(len(s.blocks)))
+
+	healthy, healthStats, err := verifySourceBlocks(ctx, s.blocks, s.policy)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed pre-compaction block verification")
+	}
+
+	for block, hs := range healthStats {
+		if !hs.Healthy() {
+			level.Warn(s.logger).Log("msg", "skipping unhealthy source block", "stats", hs)
+			stats.AddSourceBlocksUnhealthy(1)
+			skipped = append(skipped, block)
+		}
+	}
+
+	ready = make([]*bloomshipper.CloseableBlockQuerier, 0, len(healthy))
+	for _, block := range healthy {
+		md, err := block.BlockQuerier.Metadata()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read block metadata")
+		}
+
+		if !s.opts.Schema.Compatible(md.Options.Schema) {
+			level.Debug(s.logger).Log("msg", "skipping block with incompatible schema", "block", md)
+			stats.AddSourceBlocksSkippedIncompatibleSchema(1)
+			skipped = append(skipped, block)
+			continue
+		}
+
+		ready = append(ready, block)
+	}
+
+	return ready, skipped, nil
+}
+
+// Generate verifies the health of every source block, drops (or, under
+// PolicyStrict, fails on) unhealthy ones, drops any with an incompatible
+// schema, and merges what's left with the tenant's chunk store into new
+// output blocks. skipped reports every source block excluded from the
+// merge, for either reason.
+func (s *SimpleBloomGenerator) Generate(ctx context.Context) (skipped []*bloomshipper.CloseableBlockQuerier, results v1.Iterator[*v1.Block], err error) {
+	stats := FromContext(ctx)
+
+	ready, skipped, err := s.prepareSourceBlocks(ctx, stats)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, err := s.merge(ctx, ready)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to merge source blocks")
+	}
+
+	var outputBlocks []*v1.Block
+	for merged.Next() {
+		outputBlocks = append(outputBlocks, merged.At())
+	}
+	if err := merged.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to build output blocks")
+	}
+	stats.AddOutputBlocks(int64(len(outputBlocks)))
+
+	level.Debug(s.logger).Log(append([]any{"msg", "finished generating blooms"}, stats.KVArgs()...)...)
+
+	return skipped, v1.NewSliceIter(outputBlocks), nil
+}
+
+// GenCheckpoint captures enough state to resume a partially finished
+// GenerateWithContext call: the last fingerprint fully merged, the index of
+// the output block it landed in, and the bytes flushed so far.
+type GenCheckpoint struct {
+	LastFingerprint model.Fingerprint
+	OutputBlockIdx  int
+	BytesWritten    int64
+}
+
+// CheckpointFunc is invoked periodically during GenerateWithContext so a
+// caller can persist progress and resume a cancelled run later.
+type CheckpointFunc func(GenCheckpoint)
+
+// checkpointEvery controls how many merged series elapse between
+// onCheckpoint callbacks in GenerateWithContext.
+const checkpointEvery = 1024
+
+// GenerateWithContext is Generate's resumable counterpart. If checkpoint is
+// non-nil, the store iterator is fast-forwarded past
+// checkpoint.LastFingerprint via a SeekingIter before merging begins, so a
+// resumed run skips fingerprints a prior, cancelled invocation already
+// flushed. As generation proceeds, onCheckpoint is invoked roughly every
+// checkpointEvery series with the latest progress. If ctx is cancelled
+// mid-build, GenerateWithContext returns cleanly with whatever output
+// blocks have already been flushed, rather than an error, so the caller can
+// persist the last checkpoint and resume with another call later.
+func (s *SimpleBloomGenerator) GenerateWithContext(
+	ctx context.Context,
+	checkpoint *GenCheckpoint,
+	onCheckpoint CheckpointFunc,
+) (skipped []*bloomshipper.CloseableBlockQuerier, results v1.Iterator[*v1.Block], err error) {
+	store := s.store
+	if checkpoint != nil {
+		store = NewSeekingIter(store, checkpoint.LastFingerprint)
+	}
+
+	stats := FromContext(ctx)
+
+	ready, skipped, err := s.prepareSourceBlocks(ctx, stats)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputBlocks, err := s.mergeResumable(ctx, store, ready, onCheckpoint)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to merge source blocks")
+	}
+	stats.AddOutputBlocks(int64(len(outputBlocks)))
+
+	level.Debug(s.logger).Log(append([]any{"msg", "finished generating blooms"}, stats.KVArgs()...)...)
+
+	return skipped, v1.NewSliceIter(outputBlocks), nil
+}
+
+// SeekingIter wraps a v1.Iterator[*v1.Series], skipping every series with
+// fingerprint <= resumeAfter the first time it's advanced. A resumed
+// GenerateWithContext call uses it to fast-forward the store iterator past
+// fingerprints a prior, interrupted run already processed.
+type SeekingIter struct {
+	v1.Iterator[*v1.Series]
+	resumeAfter model.Fingerprint
+	seeked      bool
+}
+
+// NewSeekingIter returns a SeekingIter that skips every series with
+// fingerprint <= resumeAfter.
+func NewSeekingIter(itr v1.Iterator[*v1.Series], resumeAfter model.Fingerprint) *SeekingIter {
+	return &SeekingIter{Iterator: itr, resumeAfter: resumeAfter}
+}
+
+func (it *SeekingIter) Next() bool {
+	if it.seeked {
+		return it.Iterator.Next()
+	}
+
+	it.seeked = true
+	for it.Iterator.Next() {
+		if it.Iterator.At().Fingerprint > it.resumeAfter {
+			return true
+		}
+	}
+	return false
+}
+
+// checkpointingIter walks store in fingerprint order, resolving each series
+// to a SeriesWithBloom via resolve and invoking onSeries with its
+// fingerprint once resolved. It implements v1.Iterator[v1.SeriesWithBloom].
+type checkpointingIter struct {
+	ctx      context.Context
+	store    v1.Iterator[*v1.Series]
+	resolve  func(*v1.Series) (v1.SeriesWithBloom, error)
+	onSeries func(model.Fingerprint)
+
+	cur v1.SeriesWithBloom
+	err error
+}
+
+func (it *checkpointingIter) Next() bool {
+	if it.err != nil || it.ctx.Err() != nil || !it.store.Next() {
+		return false
+	}
+
+	series := it.store.At()
+	swb, err := it.resolve(series)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.cur = swb
+	it.onSeries(series.Fingerprint)
+	return true
+}
+
+func (it *checkpointingIter) At() v1.SeriesWithBloom { return it.cur }
+
+func (it *checkpointingIter) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.store.Err()
+}
+
+// cancelableIter stops yielding once ctx is cancelled, so an in-progress
+// BlockBuilder.BuildFrom finishes cleanly with whatever was already added
+// instead of erroring out mid-write.
+type cancelableIter struct {
+	ctx context.Context
+	v1.Iterator[v1.SeriesWithBloom]
+}
+
+func (it *cancelableIter) Next() bool {
+	if it.ctx.Err() != nil {
+		return false
+	}
+	return it.Iterator.Next()
+}
+
+// flushBlocks drains peekable into successive output blocks via
+// s.readWriterFn and s.opts, recording flush-time and bytes-written stats
+// for each. It stops once peekable reports no more series. afterFlush, if
+// non-nil, is called with each block's 1-based position in the returned
+// slice and its serialized size right after it's flushed. Shared by merge
+// and mergeResumable, which differ only in how they react to ctx being
+// done: if cancelIsErr is true, a done ctx aborts the next iteration with
+// an error (merge, which was never specified to support cancellation); if
+// false, it stops cleanly after the current flush with whatever's already
+// been built (mergeResumable, which is allowed to be cancelled mid-build).
+func (s *SimpleBloomGenerator) flushBlocks(
+	ctx context.Context,
+	peekable v1.PeekingIterator[v1.SeriesWithBloom],
+	afterFlush func(idx int, bytesWritten int64),
+	cancelIsErr bool,
+) ([]*v1.Block, error) {
+	stats := FromContext(ctx)
+
+	var outputBlocks []*v1.Block
+	for {
+		if _, ok := peekable.Peek(); !ok {
+			break
+		}
+
+		if cancelIsErr {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		writer, reader := s.readWriterFn()
+		builder, err := v1.NewBlockBuilder(s.opts, writer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create block builder")
+		}
+
+		start := time.Now()
+		if _, err := builder.BuildFrom(peekable); err != nil {
+			return nil, errors.Wrap(err, "failed to build block")
+		}
+		stats.AddBlockFlushTime(time.Since(start))
+
+		bytesWritten := int64(writer.Size())
+		stats.AddBloomBytesWritten(bytesWritten)
+
+		outputBlocks = append(outputBlocks, v1.NewBlock(reader))
+
+		if afterFlush != nil {
+			afterFlush(len(outputBlocks), bytesWritten)
+		}
+
+		if !cancelIsErr && ctx.Err() != nil {
+			break
+		}
+	}
+
+	return outputBlocks, nil
+}
+
+// mergeResumable is merge's checkpoint-aware counterpart: it builds new
+// output blocks from store and blocks exactly as merge does, but stops
+// cleanly on ctx cancellation instead of erroring, and calls onCheckpoint
+// (if non-nil) roughly every checkpointEvery series and after each output
+// block is flushed.
+func (s *SimpleBloomGenerator) mergeResumable(
+	ctx context.Context,
+	store v1.Iterator[*v1.Series],
+	blocks []*bloomshipper.CloseableBlockQuerier,
+	onCheckpoint CheckpointFunc,
+) ([]*v1.Block, error) {
+	queriers := make([]v1.PeekingIterator[v1.SeriesWithBloom], 0, len(blocks))
+	for _, block := range blocks {
+		// Defensive: don't assume verifyBlock's own Reset() left the shared
+		// querier rewound correctly; rewind it ourselves before reuse.
+		block.BlockQuerier.Reset()
+		queriers = append(queriers, v1.NewPeekingIter[v1.SeriesWithBloom](block.BlockQuerier))
+	}
+
+	stats := FromContext(ctx)
+	var (
+		lastFP        model.Fingerprint
+		bytesWritten  int64
+		blocksFlushed int
+		sinceLast     int
+	)
+
+	mergedSeries := &checkpointingIter{
+		ctx:   ctx,
+		store: store,
+		resolve: func(series *v1.Series) (v1.SeriesWithBloom, error) {
+			stats.AddSeriesIn(1)
+			return s.resolveSeries(ctx, series, queriers)
+		},
+		onSeries: func(fp model.Fingerprint) {
+			stats.AddSeriesOut(1)
+			lastFP = fp
+			sinceLast++
+			if onCheckpoint != nil && sinceLast >= checkpointEvery {
+				sinceLast = 0
+				onCheckpoint(GenCheckpoint{LastFingerprint: lastFP, OutputBlockIdx: blocksFlushed, BytesWritten: bytesWritten})
+			}
+		},
+	}
+
+	peekable := v1.NewPeekingIter[v1.SeriesWithBloom](&cancelableIter{ctx: ctx, Iterator: mergedSeries})
+
+	outputBlocks, err := s.flushBlocks(ctx, peekable, func(idx int, blockBytes int64) {
+		bytesWritten += blockBytes
+		blocksFlushed = idx
+		sinceLast = 0
+		if onCheckpoint != nil {
+			// idx is 1-based; lastFP landed in the block just flushed.
+			onCheckpoint(GenCheckpoint{LastFingerprint: lastFP, OutputBlockIdx: idx - 1, BytesWritten: bytesWritten})
+		}
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mergedSeries.Err(); err != nil {
+		return nil, errors.Wrap(err, "error iterating merged series")
+	}
+
+	return outputBlocks, nil
+}
+
+// merge builds new output blocks from mergedSeries, rolling over to a new
+// block whenever the current one reaches s.opts.MaxBlockSize. Unlike
+// mergeResumable, merge isn't resumable: a cancelled or deadline-exceeded
+// ctx aborts it with an error rather than returning a silent partial
+// result.
+func (s *SimpleBloomGenerator) merge(ctx context.Context, blocks []*bloomshipper.CloseableBlockQuerier) (v1.Iterator[*v1.Block], error) {
+	mergedSeries, err := s.mergeSeries(ctx, blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	peekable := v1.NewPeekingIter[v1.SeriesWithBloom](mergedSeries)
+	outputBlocks, err := s.flushBlocks(ctx, peekable, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return v1.NewSliceIter(outputBlocks), nil
+}
+
+// mergeSeries walks s.store in fingerprint order, reusing an already-built
+// bloom from a source block when one exists for that fingerprint and
+// populating a fresh one from the chunk store otherwise.
+func (s *SimpleBloomGenerator) mergeSeries(ctx context.Context, blocks []*bloomshipper.CloseableBlockQuerier) (v1.Iterator[v1.SeriesWithBloom], error) {
+	queriers := make([]v1.PeekingIterator[v1.SeriesWithBloom], 0, len(blocks))
+	for _, block := range blocks {
+		// Defensive: don't assume verifyBlock's own Reset() left the shared
+		// querier rewound correctly; rewind it ourselves before reuse.
+		block.BlockQuerier.Reset()
+		queriers = append(queriers, v1.NewPeekingIter[v1.SeriesWithBloom](block.BlockQuerier))
+	}
+
+	stats := FromContext(ctx)
+
+	var out []v1.SeriesWithBloom
+	for s.store.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		series := s.store.At()
+		stats.AddSeriesIn(1)
+		swb, err := s.resolveSeries(ctx, series, queriers)
+		if err != nil {
+			return nil, err
+		}
+		stats.AddSeriesOut(1)
+		out = append(out, swb)
+	}
+	if err := s.store.Err(); err != nil {
+		return nil, errors.Wrap(err, "error iterating store series")
+	}
+
+	return v1.NewSliceIter(out), nil
+}
+
+// resolveSeries returns the bloom for series, preferring one already built
+// in a source block over tokenizing the chunk store from scratch.
+func (s *SimpleBloomGenerator) resolveSeries(ctx context.Context, series *v1.Series, queriers []v1.PeekingIterator[v1.SeriesWithBloom]) (v1.SeriesWithBloom, error) {
+	for _, q := range queriers {
+		for {
+			peeked, ok := q.Peek()
+			if !ok || peeked.Series.Fingerprint > series.Fingerprint {
+				break
+			}
+			q.Next()
+			if peeked.Series.Fingerprint == series.Fingerprint {
+				return peeked, nil
+			}
+		}
+	}
+
+	return s.populate(ctx, series)
+}
+
+// populate loads series' chunks and tokenizes them into a fresh bloom.
+func (s *SimpleBloomGenerator) populate(ctx context.Context, series *v1.Series) (v1.SeriesWithBloom, error) {
+	stats := FromContext(ctx)
+
+	start := time.Now()
+	chunkIters, err := s.chunkLoader.Load(ctx, s.userID, series)
+	stats.AddChunkLoadTime(time.Since(start))
+	if err != nil {
+		return v1.SeriesWithBloom{}, errors.Wrapf(err, "failed to load chunks for series %v", series.Fingerprint)
+	}
+	stats.AddChunksHashed(int64(len(series.Chunks)))
+
+	bloom := v1.NewBloom()
+	start = time.Now()
+	err = v1.NewBloomTokenizer(s.metrics.bloomMetrics).Populate(bloom, chunkIters.itr)
+	stats.AddBloomBuildTime(time.Since(start))
+	if err != nil {
+		return v1.SeriesWithBloom{}, errors.Wrapf(err, "failed to populate bloom for series %v", series.Fingerprint)
+	}
+
+	return v1.SeriesWithBloom{Series: series, Bloom: bloom}, nil
+}
@@ -64,6 +64,10 @@ func (dummyChunkLoader) Load(_ context.Context, _ string, series *v1.Series) (*C
 }
 
 func dummyBloomGen(opts v1.BlockOptions, store v1.Iterator[*v1.Series], blocks []*v1.Block) *SimpleBloomGenerator {
+	return dummyBloomGenWithLoader(opts, store, blocks, dummyChunkLoader{})
+}
+
+func dummyBloomGenWithLoader(opts v1.BlockOptions, store v1.Iterator[*v1.Series], blocks []*v1.Block, loader ChunkLoader) *SimpleBloomGenerator {
 	bqs := make([]*bloomshipper.CloseableBlockQuerier, 0, len(blocks))
 	for _, b := range blocks {
 		bqs = append(bqs, &bloomshipper.CloseableBlockQuerier{
@@ -75,7 +79,7 @@ func dummyBloomGen(opts v1.BlockOptions, store v1.Iterator[*v1.Series], blocks [
 		"fake",
 		opts,
 		store,
-		dummyChunkLoader{},
+		loader,
 		bqs,
 		func() (v1.BlockWriter, v1.BlockReader) {
 			indexBuf := bytes.NewBuffer(nil)
@@ -87,12 +91,29 @@ func dummyBloomGen(opts v1.BlockOptions, store v1.Iterator[*v1.Series], blocks [
 	)
 }
 
+// cancelAfterNChunkLoader cancels its owning context after n series have
+// been loaded, simulating an operator cancelling a long-running generation.
+type cancelAfterNChunkLoader struct {
+	inner  ChunkLoader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (c *cancelAfterNChunkLoader) Load(ctx context.Context, userID string, series *v1.Series) (*ChunkItersByFingerprint, error) {
+	c.n--
+	if c.n <= 0 {
+		c.cancel()
+	}
+	return c.inner.Load(ctx, userID, series)
+}
+
 func TestSimpleBloomGenerator(t *testing.T) {
 	const maxBlockSize = 100 << 20 // 100MB
 	for _, tc := range []struct {
 		desc                                   string
 		fromSchema, toSchema                   v1.BlockOptions
 		sourceBlocks, numSkipped, outputBlocks int
+		reusesSourceBlooms                     bool
 	}{
 		{
 			desc:         "SkipsIncompatibleSchemas",
@@ -103,20 +124,22 @@ func TestSimpleBloomGenerator(t *testing.T) {
 			outputBlocks: 1,
 		},
 		{
-			desc:         "CombinesBlocks",
-			fromSchema:   v1.NewBlockOptions(4, 0, maxBlockSize),
-			toSchema:     v1.NewBlockOptions(4, 0, maxBlockSize),
-			sourceBlocks: 2,
-			numSkipped:   0,
-			outputBlocks: 1,
+			desc:               "CombinesBlocks",
+			fromSchema:         v1.NewBlockOptions(4, 0, maxBlockSize),
+			toSchema:           v1.NewBlockOptions(4, 0, maxBlockSize),
+			sourceBlocks:       2,
+			numSkipped:         0,
+			outputBlocks:       1,
+			reusesSourceBlooms: true,
 		},
 		{
-			desc:         "MaxBlockSize",
-			fromSchema:   v1.NewBlockOptions(4, 0, maxBlockSize),
-			toSchema:     v1.NewBlockOptions(4, 0, 1<<10), // 1KB
-			sourceBlocks: 2,
-			numSkipped:   0,
-			outputBlocks: 3,
+			desc:               "MaxBlockSize",
+			fromSchema:         v1.NewBlockOptions(4, 0, maxBlockSize),
+			toSchema:           v1.NewBlockOptions(4, 0, 1<<10), // 1KB
+			sourceBlocks:       2,
+			numSkipped:         0,
+			outputBlocks:       3,
+			reusesSourceBlooms: true,
 		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -129,15 +152,18 @@ func TestSimpleBloomGenerator(t *testing.T) {
 			)
 
 			gen := dummyBloomGen(tc.toSchema, storeItr, sourceBlocks)
-			skipped, results, err := gen.Generate(context.Background())
+			stats, ctx := ContextWithEmptyStats(context.Background())
+			skipped, results, err := gen.Generate(ctx)
 			require.Nil(t, err)
 			require.Equal(t, tc.numSkipped, len(skipped))
+			require.Equal(t, int64(tc.sourceBlocks), stats.SourceBlocksConsidered.Load())
 
 			var outputBlocks []*v1.Block
 			for results.Next() {
 				outputBlocks = append(outputBlocks, results.At())
 			}
 			require.Equal(t, tc.outputBlocks, len(outputBlocks))
+			require.Equal(t, int64(tc.outputBlocks), stats.OutputBlocks.Load())
 
 			// Check all the input series are present in the output blocks.
 			expectedRefs := v1.PointerSlice(data)
@@ -151,7 +177,201 @@ func TestSimpleBloomGenerator(t *testing.T) {
 			require.Equal(t, len(expectedRefs), len(outputRefs))
 			for i := range expectedRefs {
 				require.Equal(t, expectedRefs[i].Series, outputRefs[i].Series)
+
+				// When the source blocks' schema is compatible with the
+				// target, merge must reuse their already-built blooms
+				// rather than re-tokenizing from the (empty) chunk loader:
+				// dummyChunkLoader never returns any chunks, so a
+				// freshly-populated bloom would come out empty.
+				if tc.reusesSourceBlooms {
+					require.Equal(t, expectedRefs[i].Bloom, outputRefs[i].Bloom)
+				}
 			}
 		})
 	}
 }
+
+// dummyBloomGenWithPolicy is like dummyBloomGen but lets the caller pick the
+// verification policy applied to the source blocks.
+func dummyBloomGenWithPolicy(opts v1.BlockOptions, store v1.Iterator[*v1.Series], blocks []*v1.Block, policy VerificationPolicy) *SimpleBloomGenerator {
+	return dummyBloomGen(opts, store, blocks).WithVerificationPolicy(policy)
+}
+
+func TestSimpleBloomGenerator_VerifiesSourceBlocks(t *testing.T) {
+	const maxBlockSize = 100 << 20 // 100MB
+	opts := v1.NewBlockOptions(4, 0, maxBlockSize)
+
+	// Generate 100 series within the fingerprint range [0, 1], so the
+	// block's fingerprints cannot be strictly increasing, simulating a
+	// malformed source block.
+	unhealthyBlocks, data := blocksFromSchemaWithRange(t, 1, opts, 0, 1)
+	healthyBlocks, healthyData := blocksFromSchemaWithRange(t, 1, opts, 0, 0xffff)
+
+	allData := append(append([]v1.SeriesWithBloom{}, data...), healthyData...)
+	storeItr := v1.NewMapIter[v1.SeriesWithBloom, *v1.Series](
+		v1.NewSliceIter[v1.SeriesWithBloom](allData),
+		func(swb v1.SeriesWithBloom) *v1.Series {
+			return swb.Series
+		},
+	)
+
+	t.Run("lenient skips the unhealthy block", func(t *testing.T) {
+		gen := dummyBloomGenWithPolicy(opts, storeItr, append(unhealthyBlocks, healthyBlocks...), PolicyLenient)
+		skipped, results, err := gen.Generate(context.Background())
+		require.Nil(t, err)
+		require.Len(t, skipped, 1)
+
+		var outputBlocks []*v1.Block
+		for results.Next() {
+			outputBlocks = append(outputBlocks, results.At())
+		}
+		require.Greater(t, len(outputBlocks), 0)
+	})
+
+	t.Run("strict fails the job", func(t *testing.T) {
+		gen := dummyBloomGenWithPolicy(opts, storeItr, append(unhealthyBlocks, healthyBlocks...), PolicyStrict)
+		_, _, err := gen.Generate(context.Background())
+		require.Error(t, err)
+	})
+}
+
+// buildBlock builds a single block from data, bypassing blocksFromSchema's
+// ordering assumptions so tests can hand it deliberately malformed fixtures.
+func buildBlock(t *testing.T, opts v1.BlockOptions, data []v1.SeriesWithBloom) *v1.Block {
+	indexBuf := bytes.NewBuffer(nil)
+	bloomsBuf := bytes.NewBuffer(nil)
+	writer := v1.NewMemoryBlockWriter(indexBuf, bloomsBuf)
+	reader := v1.NewByteReader(indexBuf, bloomsBuf)
+
+	builder, err := v1.NewBlockBuilder(opts, writer)
+	require.Nil(t, err)
+
+	_, err = builder.BuildFrom(v1.NewSliceIter(data))
+	require.Nil(t, err)
+
+	return v1.NewBlock(reader)
+}
+
+func TestVerifyBlock(t *testing.T) {
+	const maxBlockSize = 100 << 20 // 100MB
+	opts := v1.NewBlockOptions(4, 0, maxBlockSize)
+
+	t.Run("detects non-monotonic fingerprints", func(t *testing.T) {
+		data, _ := v1.MkBasicSeriesWithBlooms(4, 100, 0, 0xffff, 0, 10000)
+		// swap two series so fingerprints are no longer strictly increasing.
+		data[1], data[2] = data[2], data[1]
+
+		stats, err := verifyBlock(context.Background(), v1.NewBlockQuerier(buildBlock(t, opts, data)))
+		require.Nil(t, err)
+		require.Greater(t, stats.MalformedSeriesCount, 0)
+	})
+
+	t.Run("detects unsorted, overlapping chunks", func(t *testing.T) {
+		data, _ := v1.MkBasicSeriesWithBlooms(4, 100, 0, 0xffff, 0, 10000)
+		// force the first series' chunks out of order and overlapping.
+		chunks := data[0].Series.Chunks
+		require.Greater(t, len(chunks), 1)
+		chunks[0], chunks[1] = chunks[1], chunks[0]
+		chunks[1].Through = chunks[0].From + 1
+
+		stats, err := verifyBlock(context.Background(), v1.NewBlockQuerier(buildBlock(t, opts, data)))
+		require.Nil(t, err)
+		require.Greater(t, stats.MalformedSeriesCount, 0)
+	})
+
+	t.Run("detects a truncated bloom's bit-length mismatch", func(t *testing.T) {
+		data, _ := v1.MkBasicSeriesWithBlooms(4, 100, 0, 0xffff, 0, 10000)
+		// replace the first series' bloom with an empty one, as if it had
+		// been truncated and never fully populated.
+		data[0].Bloom = v1.NewBloom()
+
+		stats, err := verifyBlock(context.Background(), v1.NewBlockQuerier(buildBlock(t, opts, data)))
+		require.Nil(t, err)
+		require.Greater(t, stats.BitLengthMismatches, 0)
+	})
+}
+
+// Generate, unlike GenerateWithContext, isn't resumable: it must fail
+// loudly on a cancelled ctx instead of returning a silent partial result.
+func TestSimpleBloomGenerator_Generate_ErrorsOnCancel(t *testing.T) {
+	const maxBlockSize = 100 << 20 // 100MB
+	opts := v1.NewBlockOptions(4, 0, maxBlockSize)
+
+	data, _ := v1.MkBasicSeriesWithBlooms(100, 10000, 0, 0xffff, 0, 10000)
+	storeItr := v1.NewMapIter[v1.SeriesWithBloom, *v1.Series](
+		v1.NewSliceIter[v1.SeriesWithBloom](data),
+		func(swb v1.SeriesWithBloom) *v1.Series { return swb.Series },
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	loader := &cancelAfterNChunkLoader{inner: dummyChunkLoader{}, n: 10, cancel: cancel}
+	gen := dummyBloomGenWithLoader(opts, storeItr, nil, loader)
+
+	_, _, err := gen.Generate(ctx)
+	require.Error(t, err)
+}
+
+func TestSimpleBloomGenerator_GenerateWithContext_ResumesAfterCancel(t *testing.T) {
+	const maxBlockSize = 100 << 20 // 100MB
+	opts := v1.NewBlockOptions(4, 0, maxBlockSize)
+
+	// No source blocks: every series must be populated via the chunk
+	// loader, so cancelAfterNChunkLoader's cancel() is guaranteed to fire
+	// partway through rather than being skipped by source-block reuse.
+	data, _ := v1.MkBasicSeriesWithBlooms(100, 10000, 0, 0xffff, 0, 10000)
+	newStoreItr := func() v1.Iterator[*v1.Series] {
+		return v1.NewMapIter[v1.SeriesWithBloom, *v1.Series](
+			v1.NewSliceIter[v1.SeriesWithBloom](data),
+			func(swb v1.SeriesWithBloom) *v1.Series { return swb.Series },
+		)
+	}
+
+	collectFPs := func(itr v1.Iterator[*v1.Block]) (fps []model.Fingerprint) {
+		for itr.Next() {
+			bq := itr.At().Querier()
+			for bq.Next() {
+				fps = append(fps, bq.At().Series.Fingerprint)
+			}
+		}
+		return fps
+	}
+
+	// Cancel partway through, recording every checkpoint along the way.
+	ctx, cancel := context.WithCancel(context.Background())
+	loader := &cancelAfterNChunkLoader{inner: dummyChunkLoader{}, n: 10, cancel: cancel}
+	gen := dummyBloomGenWithLoader(opts, newStoreItr(), nil, loader)
+
+	var checkpoints []GenCheckpoint
+	_, firstResults, err := gen.GenerateWithContext(ctx, nil, func(c GenCheckpoint) {
+		checkpoints = append(checkpoints, c)
+	})
+	require.Nil(t, err)
+	require.Error(t, ctx.Err())
+	require.NotEmpty(t, checkpoints)
+
+	firstFPs := collectFPs(firstResults)
+	require.NotEmpty(t, firstFPs)
+	require.Less(t, len(firstFPs), len(data))
+
+	// Resume from the last checkpoint, using a fresh, uncancelled context.
+	lastCheckpoint := checkpoints[len(checkpoints)-1]
+	resumedGen := dummyBloomGenWithLoader(opts, newStoreItr(), nil, dummyChunkLoader{})
+	_, resumedResults, err := resumedGen.GenerateWithContext(context.Background(), &lastCheckpoint, nil)
+	require.Nil(t, err)
+
+	resumedFPs := collectFPs(resumedResults)
+	require.NotEmpty(t, resumedFPs)
+	for _, fp := range resumedFPs {
+		require.Greater(t, fp, lastCheckpoint.LastFingerprint)
+	}
+
+	// Together, the interrupted run and the resumed run must cover exactly
+	// the same series as an uninterrupted run.
+	baselineGen := dummyBloomGenWithLoader(opts, newStoreItr(), nil, dummyChunkLoader{})
+	_, baselineResults, err := baselineGen.Generate(context.Background())
+	require.Nil(t, err)
+	baselineFPs := collectFPs(baselineResults)
+
+	gotFPs := append(append([]model.Fingerprint{}, firstFPs...), resumedFPs...)
+	require.ElementsMatch(t, baselineFPs, gotFPs)
+}
@@ -0,0 +1,151 @@
+package bloomcompactor
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// Stats is the bloomcompactor's write-path counterpart to
+// bloomgateway.Stats: a context-scoped set of counters and phase durations
+// populated as SimpleBloomGenerator runs, so operators get symmetric
+// telemetry on both the read and write paths.
+type Stats struct {
+	SourceBlocksConsidered                atomic.Int64
+	SourceBlocksSkippedIncompatibleSchema atomic.Int64
+	SourceBlocksUnhealthy                 atomic.Int64
+	SeriesIn, SeriesOut                   atomic.Int64
+	ChunksHashed                          atomic.Int64
+	BloomBytesWritten                     atomic.Int64
+	OutputBlocks                          atomic.Int64
+
+	ChunkLoadTime, BloomBuildTime, BlockFlushTime atomic.Duration
+}
+
+type statsKey int
+
+var ctxKey = statsKey(0)
+
+// ContextWithEmptyStats returns a context with empty stats.
+func ContextWithEmptyStats(ctx context.Context) (*Stats, context.Context) {
+	stats := &Stats{}
+	ctx = context.WithValue(ctx, ctxKey, stats)
+	return stats, ctx
+}
+
+// FromContext gets the Stats out of the Context. Returns nil if stats have
+// not been initialised in the context.
+func FromContext(ctx context.Context) *Stats {
+	o := ctx.Value(ctxKey)
+	if o == nil {
+		return nil
+	}
+	return o.(*Stats)
+}
+
+// Duration aggregates the total phase duration.
+func (s *Stats) Duration() (dur time.Duration) {
+	dur += s.ChunkLoadTime.Load()
+	dur += s.BloomBuildTime.Load()
+	dur += s.BlockFlushTime.Load()
+	return
+}
+
+func (s *Stats) KVArgs() []any {
+	if s == nil {
+		return []any{}
+	}
+
+	return []any{
+		"source_blocks_considered", s.SourceBlocksConsidered.Load(),
+		"source_blocks_skipped_incompatible_schema", s.SourceBlocksSkippedIncompatibleSchema.Load(),
+		"source_blocks_unhealthy", s.SourceBlocksUnhealthy.Load(),
+		"series_in", s.SeriesIn.Load(),
+		"series_out", s.SeriesOut.Load(),
+		"chunks_hashed", s.ChunksHashed.Load(),
+		"bloom_bytes_written", s.BloomBytesWritten.Load(),
+		"output_blocks", s.OutputBlocks.Load(),
+		"chunk_load_time", s.ChunkLoadTime.Load(),
+		"bloom_build_time", s.BloomBuildTime.Load(),
+		"block_flush_time", s.BlockFlushTime.Load(),
+		"duration", s.Duration(),
+	}
+}
+
+func (s *Stats) AddSourceBlocksConsidered(n int64) {
+	if s == nil {
+		return
+	}
+	s.SourceBlocksConsidered.Add(n)
+}
+
+func (s *Stats) AddSourceBlocksSkippedIncompatibleSchema(n int64) {
+	if s == nil {
+		return
+	}
+	s.SourceBlocksSkippedIncompatibleSchema.Add(n)
+}
+
+func (s *Stats) AddSourceBlocksUnhealthy(n int64) {
+	if s == nil {
+		return
+	}
+	s.SourceBlocksUnhealthy.Add(n)
+}
+
+func (s *Stats) AddSeriesIn(n int64) {
+	if s == nil {
+		return
+	}
+	s.SeriesIn.Add(n)
+}
+
+func (s *Stats) AddSeriesOut(n int64) {
+	if s == nil {
+		return
+	}
+	s.SeriesOut.Add(n)
+}
+
+func (s *Stats) AddChunksHashed(n int64) {
+	if s == nil {
+		return
+	}
+	s.ChunksHashed.Add(n)
+}
+
+func (s *Stats) AddBloomBytesWritten(n int64) {
+	if s == nil {
+		return
+	}
+	s.BloomBytesWritten.Add(n)
+}
+
+func (s *Stats) AddOutputBlocks(n int64) {
+	if s == nil {
+		return
+	}
+	s.OutputBlocks.Add(n)
+}
+
+func (s *Stats) AddChunkLoadTime(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.ChunkLoadTime.Add(d)
+}
+
+func (s *Stats) AddBloomBuildTime(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.BloomBuildTime.Add(d)
+}
+
+func (s *Stats) AddBlockFlushTime(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.BlockFlushTime.Add(d)
+}